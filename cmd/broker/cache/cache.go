@@ -0,0 +1,20 @@
+package cache
+
+import "context"
+
+// CacheAccessMetricName is the {layer, outcome} labeled counter hit/miss
+// outcomes are recorded against: by RedisCache (layer L2) and by
+// TieredCache for its L1 lookups. LRUCache on its own records none.
+const CacheAccessMetricName = "cache_access_count"
+
+// CacheLatencyMetricName is the {layer} labeled histogram round-trip
+// latency is recorded against for backends with real network/IO cost.
+const CacheLatencyMetricName = "cache_round_trip_duration_ms"
+
+// Cache is a generic read/write cache keyed by K with values V.
+// Implementations may be in-process (LRUCache), distributed (RedisCache),
+// or a composition of both (TieredCache).
+type Cache[K comparable, V any] interface {
+	Get(ctx context.Context, key K) (V, bool, error)
+	Set(ctx context.Context, key K, value V) error
+}
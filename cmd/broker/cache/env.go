@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/alexchomiak/broker/cmd/broker/metric"
+	"github.com/redis/go-redis/v9"
+)
+
+// * Defaults mirror the previous hard-coded instance-memory cache (100
+// * entries) and a conservative TTL for the distributed backends.
+const (
+	defaultCacheSize = 100
+	defaultCacheTTL  = 10 * time.Minute
+)
+
+// NewFromEnv builds the Cache[string,string] the broker should use,
+// selected by CACHE_BACKEND:
+//   - "memory" (default): the original in-process LRU. Not shared across
+//     instances.
+//   - "redis": a distributed Redis-backed cache with TTL.
+//   - "tiered": the in-process LRU as an L1 read-through/write-through in
+//     front of the Redis backend.
+func NewFromEnv(metrics *metric.MetricPublisher) (Cache[string, string], error) {
+	size := defaultCacheSize
+	if v := os.Getenv("CACHE_SIZE"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			size = n
+		}
+	}
+
+	switch os.Getenv("CACHE_BACKEND") {
+	case "redis":
+		return newRedisFromEnv(metrics), nil
+	case "tiered":
+		l1, err := NewLRUCache[string, string](size)
+		if err != nil {
+			return nil, err
+		}
+		return NewTieredCache[string, string](l1, newRedisFromEnv(metrics), metrics), nil
+	default:
+		return NewLRUCache[string, string](size)
+	}
+}
+
+func newRedisFromEnv(metrics *metric.MetricPublisher) *RedisCache[string, string] {
+	client := redis.NewClient(&redis.Options{
+		Addr: envOr("REDIS_ADDR", "localhost:6379"),
+	})
+
+	ttl := defaultCacheTTL
+	if v := os.Getenv("CACHE_TTL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			ttl = d
+		}
+	}
+
+	return NewRedisCache[string, string](client, envOr("CACHE_KEY_PREFIX", "broker:cache:"), ttl, metrics)
+}
+
+func envOr(key, fallback string) string {
+	if v, ok := os.LookupEnv(key); ok {
+		return v
+	}
+	return fallback
+}
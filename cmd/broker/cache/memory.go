@@ -0,0 +1,41 @@
+package cache
+
+import (
+	"context"
+	"sync"
+
+	"github.com/hashicorp/golang-lru/v2/simplelru"
+)
+
+// LRUCache is an in-process LRU-backed Cache. It is not shared across
+// instances; see RedisCache for a distributed alternative, or TieredCache
+// to use one as an L1 in front of the other.
+type LRUCache[K comparable, V any] struct {
+	mu  sync.Mutex
+	lru *simplelru.LRU[K, V]
+}
+
+// NewLRUCache builds an LRUCache holding at most size entries.
+func NewLRUCache[K comparable, V any](size int) (*LRUCache[K, V], error) {
+	lru, err := simplelru.NewLRU[K, V](size, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &LRUCache[K, V]{lru: lru}, nil
+}
+
+func (c *LRUCache[K, V]) Get(_ context.Context, key K) (V, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v, ok := c.lru.Get(key)
+	return v, ok, nil
+}
+
+func (c *LRUCache[K, V]) Set(_ context.Context, key K, value V) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.lru.Add(key, value)
+	return nil
+}
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/alexchomiak/broker/cmd/broker/metric"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisCache is a Redis-backed, TTL'd Cache shared across instances. Keys
+// and values are constrained to string-like types since they round-trip
+// through Redis as plain strings.
+type RedisCache[K ~string, V ~string] struct {
+	client  *redis.Client
+	prefix  string
+	ttl     time.Duration
+	metrics *metric.MetricPublisher
+}
+
+// NewRedisCache builds a RedisCache against client, prefixing every key
+// with prefix and setting ttl on writes. metrics may be nil to disable
+// instrumentation.
+func NewRedisCache[K ~string, V ~string](client *redis.Client, prefix string, ttl time.Duration, metrics *metric.MetricPublisher) *RedisCache[K, V] {
+	return &RedisCache[K, V]{client: client, prefix: prefix, ttl: ttl, metrics: metrics}
+}
+
+func (c *RedisCache[K, V]) Get(ctx context.Context, key K) (V, bool, error) {
+	start := time.Now()
+	val, err := c.client.Get(ctx, c.fullKey(key)).Result()
+	c.observeLatency(start)
+
+	var zero V
+	switch {
+	case err == redis.Nil:
+		c.recordOutcome(false)
+		return zero, false, nil
+	case err != nil:
+		return zero, false, err
+	}
+
+	c.recordOutcome(true)
+	return V(val), true, nil
+}
+
+func (c *RedisCache[K, V]) Set(ctx context.Context, key K, value V) error {
+	start := time.Now()
+	err := c.client.Set(ctx, c.fullKey(key), string(value), c.ttl).Err()
+	c.observeLatency(start)
+	return err
+}
+
+func (c *RedisCache[K, V]) fullKey(key K) string {
+	return c.prefix + string(key)
+}
+
+func (c *RedisCache[K, V]) recordOutcome(hit bool) {
+	if c.metrics == nil {
+		return
+	}
+	outcome := "miss"
+	if hit {
+		outcome = "hit"
+	}
+	c.metrics.PublishLabeledCounter(CacheAccessMetricName, map[string]string{"layer": "L2", "outcome": outcome})
+}
+
+func (c *RedisCache[K, V]) observeLatency(start time.Time) {
+	if c.metrics == nil {
+		return
+	}
+	c.metrics.PublishLabeledHistogram(
+		CacheLatencyMetricName,
+		map[string]string{"layer": "L2"},
+		float64(time.Since(start).Milliseconds()),
+	)
+}
@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/alexchomiak/broker/cmd/broker/metric"
+)
+
+// TieredCache composes a fast in-process L1 in front of a shared L2,
+// reading through to L2 on an L1 miss (and populating L1 from the result)
+// and writing through to both on Set.
+type TieredCache[K comparable, V any] struct {
+	l1      Cache[K, V]
+	l2      Cache[K, V]
+	metrics *metric.MetricPublisher
+}
+
+// NewTieredCache composes l1 in front of l2. metrics may be nil to
+// disable L1 hit/miss instrumentation (l2 instruments itself).
+func NewTieredCache[K comparable, V any](l1, l2 Cache[K, V], metrics *metric.MetricPublisher) *TieredCache[K, V] {
+	return &TieredCache[K, V]{l1: l1, l2: l2, metrics: metrics}
+}
+
+func (c *TieredCache[K, V]) Get(ctx context.Context, key K) (V, bool, error) {
+	v, ok, err := c.l1.Get(ctx, key)
+	if err != nil {
+		var zero V
+		return zero, false, err
+	}
+	c.recordL1Outcome(ok)
+	if ok {
+		return v, true, nil
+	}
+
+	v, ok, err = c.l2.Get(ctx, key)
+	if err != nil || !ok {
+		return v, ok, err
+	}
+
+	// * Write-through into L1 so the next read for this key is fast.
+	_ = c.l1.Set(ctx, key, v)
+	return v, true, nil
+}
+
+func (c *TieredCache[K, V]) Set(ctx context.Context, key K, value V) error {
+	if err := c.l2.Set(ctx, key, value); err != nil {
+		return err
+	}
+	return c.l1.Set(ctx, key, value)
+}
+
+func (c *TieredCache[K, V]) recordL1Outcome(hit bool) {
+	if c.metrics == nil {
+		return
+	}
+	outcome := "miss"
+	if hit {
+		outcome = "hit"
+	}
+	c.metrics.PublishLabeledCounter(CacheAccessMetricName, map[string]string{"layer": "L1", "outcome": outcome})
+}
@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTieredCacheReadsThroughAndPopulatesL1(t *testing.T) {
+	ctx := context.Background()
+
+	l1, _ := NewLRUCache[string, string](10)
+	l2, _ := NewLRUCache[string, string](10)
+	tiered := NewTieredCache[string, string](l1, l2, nil)
+
+	if err := l2.Set(ctx, "ip-1", "user-1"); err != nil {
+		t.Fatalf("unexpected error seeding L2: %v", err)
+	}
+
+	if _, ok, _ := l1.Get(ctx, "ip-1"); ok {
+		t.Fatalf("expected L1 to be empty before the first tiered read")
+	}
+
+	v, ok, err := tiered.Get(ctx, "ip-1")
+	if err != nil || !ok || v != "user-1" {
+		t.Fatalf("got (%q, %v, %v), want (\"user-1\", true, nil)", v, ok, err)
+	}
+
+	if v, ok, _ := l1.Get(ctx, "ip-1"); !ok || v != "user-1" {
+		t.Fatalf("expected L2 hit to populate L1, got (%q, %v)", v, ok)
+	}
+}
+
+func TestTieredCacheSetWritesThroughBothLayers(t *testing.T) {
+	ctx := context.Background()
+
+	l1, _ := NewLRUCache[string, string](10)
+	l2, _ := NewLRUCache[string, string](10)
+	tiered := NewTieredCache[string, string](l1, l2, nil)
+
+	if err := tiered.Set(ctx, "ip-1", "user-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for name, c := range map[string]Cache[string, string]{"L1": l1, "L2": l2} {
+		if v, ok, _ := c.Get(ctx, "ip-1"); !ok || v != "user-1" {
+			t.Fatalf("expected %s to have been written through, got (%q, %v)", name, v, ok)
+		}
+	}
+}
+
+func TestTieredCacheMissesWhenAbsentFromBothLayers(t *testing.T) {
+	ctx := context.Background()
+
+	l1, _ := NewLRUCache[string, string](10)
+	l2, _ := NewLRUCache[string, string](10)
+	tiered := NewTieredCache[string, string](l1, l2, nil)
+
+	if _, ok, err := tiered.Get(ctx, "missing"); ok || err != nil {
+		t.Fatalf("expected a clean miss, got ok=%v err=%v", ok, err)
+	}
+}
@@ -0,0 +1,47 @@
+package client
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+)
+
+// ErrNoEndpoints is returned by a Balancer when there are no resolved
+// endpoints to pick from.
+var ErrNoEndpoints = errors.New("client: no backend endpoints available")
+
+// Balancer selects one endpoint from a set of candidates resolved by a
+// Publisher.
+type Balancer interface {
+	Pick(endpoints []Endpoint) (Endpoint, error)
+}
+
+// RoundRobinBalancer cycles through endpoints in the order they were
+// resolved.
+type RoundRobinBalancer struct {
+	mu   sync.Mutex
+	next int
+}
+
+func (b *RoundRobinBalancer) Pick(endpoints []Endpoint) (Endpoint, error) {
+	if len(endpoints) == 0 {
+		return Endpoint{}, ErrNoEndpoints
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	e := endpoints[b.next%len(endpoints)]
+	b.next++
+	return e, nil
+}
+
+// RandomBalancer picks a uniformly random endpoint on every call.
+type RandomBalancer struct{}
+
+func (RandomBalancer) Pick(endpoints []Endpoint) (Endpoint, error) {
+	if len(endpoints) == 0 {
+		return Endpoint{}, ErrNoEndpoints
+	}
+	return endpoints[rand.Intn(len(endpoints))], nil
+}
@@ -0,0 +1,35 @@
+package client
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRoundRobinBalancerCycles(t *testing.T) {
+	b := &RoundRobinBalancer{}
+	endpoints := []Endpoint{{Target: "a"}, {Target: "b"}, {Target: "c"}}
+
+	var picked []string
+	for i := 0; i < 6; i++ {
+		e, err := b.Pick(endpoints)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		picked = append(picked, e.Target)
+	}
+
+	want := []string{"a", "b", "c", "a", "b", "c"}
+	for i, w := range want {
+		if picked[i] != w {
+			t.Fatalf("pick %d = %s, want %s", i, picked[i], w)
+		}
+	}
+}
+
+func TestBalancersReturnErrNoEndpoints(t *testing.T) {
+	for _, b := range []Balancer{&RoundRobinBalancer{}, RandomBalancer{}} {
+		if _, err := b.Pick(nil); !errors.Is(err, ErrNoEndpoints) {
+			t.Fatalf("expected ErrNoEndpoints for empty endpoint set, got %v", err)
+		}
+	}
+}
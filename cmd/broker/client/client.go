@@ -0,0 +1,197 @@
+package client
+
+import (
+	"context"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/alexchomiak/broker/cmd/broker/metric"
+	"github.com/alexchomiak/broker/cmd/broker/request"
+)
+
+// OutboundRequestCountMetricName is the {backend, outcome} labeled counter
+// every Publisher.Do attempt is recorded against.
+const OutboundRequestCountMetricName = "outbound_request_count"
+
+// Endpoint is a single resolved backend instance.
+type Endpoint struct {
+	Target string
+	Port   uint16
+}
+
+// Address formats the endpoint as a dialable host:port.
+func (e Endpoint) Address() string {
+	return net.JoinHostPort(e.Target, strconv.Itoa(int(e.Port)))
+}
+
+// Config configures a Publisher, populated from env vars by
+// NewConfigFromEnv.
+type Config struct {
+	SRVName         string
+	RefreshInterval time.Duration
+	MaxAttempts     int
+}
+
+// * Defaults mirror what a single-region backend pool with a handful of
+// * instances would want out of the box.
+const (
+	defaultRefreshInterval = 30 * time.Second
+	defaultMaxAttempts     = 3
+)
+
+// NewConfigFromEnv builds a Config from BACKEND_SRV_NAME,
+// BACKEND_REFRESH_INTERVAL, and BACKEND_MAX_ATTEMPTS.
+func NewConfigFromEnv() Config {
+	cfg := Config{
+		SRVName:         os.Getenv("BACKEND_SRV_NAME"),
+		RefreshInterval: defaultRefreshInterval,
+		MaxAttempts:     defaultMaxAttempts,
+	}
+
+	if v := os.Getenv("BACKEND_REFRESH_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.RefreshInterval = d
+		}
+	}
+	if v := os.Getenv("BACKEND_MAX_ATTEMPTS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 1 {
+			cfg.MaxAttempts = n
+		}
+	}
+
+	return cfg
+}
+
+// Attempt is invoked once per retry against a selected endpoint. span is
+// the per-attempt child span; callers making an HTTP request should call
+// span.Inject to attach trace-context propagation headers before dialing.
+// Errors trigger a retry against a (possibly different) endpoint, up to
+// Config.MaxAttempts.
+type Attempt func(ctx context.Context, endpoint Endpoint, span request.Span) error
+
+// Publisher resolves backend instances for an SRV name on a refresh
+// ticker and dispatches outbound attempts against them through a
+// pluggable Balancer, retrying with per-attempt deadlines.
+//
+// This broker doesn't make any outbound calls of its own yet, so nothing
+// in main.go constructs a Publisher today; it's plumbing for whichever
+// route is the first to proxy or fan out to a backend service, at which
+// point that call site owns a Publisher and an Attempt that does the
+// actual dial. Until then, the background refresh loop a Publisher starts
+// only runs once something calls NewPublisher.
+type Publisher struct {
+	cfg      Config
+	resolver *net.Resolver
+	balancer Balancer
+	metrics  *metric.MetricPublisher
+
+	mu        sync.RWMutex
+	endpoints []Endpoint
+
+	stop chan struct{}
+}
+
+// NewPublisher builds a Publisher for cfg, performing an initial SRV
+// resolution before starting the background refresh loop. balancer picks
+// among resolved endpoints; metrics instruments attempt outcomes.
+func NewPublisher(cfg Config, balancer Balancer, metrics *metric.MetricPublisher) *Publisher {
+	p := &Publisher{
+		cfg:      cfg,
+		resolver: net.DefaultResolver,
+		balancer: balancer,
+		metrics:  metrics,
+		stop:     make(chan struct{}),
+	}
+
+	p.refresh()
+	go p.refreshLoop()
+
+	return p
+}
+
+// Close stops the background refresh loop. The Publisher must not be used
+// after Close.
+func (p *Publisher) Close() { close(p.stop) }
+
+func (p *Publisher) refreshLoop() {
+	ticker := time.NewTicker(p.cfg.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.refresh()
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+// refresh re-resolves the SRV name, keeping the last known-good endpoint
+// cache in place if the lookup fails.
+func (p *Publisher) refresh() {
+	_, srvs, err := p.resolver.LookupSRV(context.Background(), "", "", p.cfg.SRVName)
+	if err != nil {
+		return
+	}
+
+	endpoints := make([]Endpoint, 0, len(srvs))
+	for _, srv := range srvs {
+		endpoints = append(endpoints, Endpoint{Target: srv.Target, Port: srv.Port})
+	}
+
+	p.mu.Lock()
+	p.endpoints = endpoints
+	p.mu.Unlock()
+}
+
+func (p *Publisher) pick() (Endpoint, error) {
+	p.mu.RLock()
+	endpoints := p.endpoints
+	p.mu.RUnlock()
+	return p.balancer.Pick(endpoints)
+}
+
+// Do picks an endpoint via the Publisher's Balancer and invokes attempt
+// against it, retrying up to Config.MaxAttempts times on error. Each
+// attempt runs as a child span of parentSpan, bounded by timeout, and its
+// outcome is published through metrics as a {backend, outcome} labeled
+// counter.
+func (p *Publisher) Do(ctx context.Context, parentSpan request.Span, timeout time.Duration, attempt Attempt) error {
+	var lastErr error
+
+	for i := 0; i < p.cfg.MaxAttempts; i++ {
+		endpoint, err := p.pick()
+		if err != nil {
+			return err
+		}
+
+		attemptSpan, attemptCtx := parentSpan.StartChild(ctx, "outbound-attempt")
+		attemptSpan.Tag("backend", endpoint.Address())
+
+		attemptCtx, cancel := context.WithTimeout(attemptCtx, timeout)
+		lastErr = attempt(attemptCtx, endpoint, attemptSpan)
+		cancel()
+		attemptSpan.Finish()
+
+		outcome := "success"
+		if lastErr != nil {
+			outcome = "failure"
+		}
+		if p.metrics != nil {
+			p.metrics.PublishLabeledCounter(OutboundRequestCountMetricName, map[string]string{
+				"backend": endpoint.Target,
+				"outcome": outcome,
+			})
+		}
+
+		if lastErr == nil {
+			return nil
+		}
+	}
+
+	return lastErr
+}
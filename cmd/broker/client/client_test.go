@@ -0,0 +1,25 @@
+package client
+
+import (
+	"testing"
+)
+
+// TestNewConfigFromEnvRejectsNonPositiveMaxAttempts asserts that a
+// zero/negative/malformed BACKEND_MAX_ATTEMPTS falls back to
+// defaultMaxAttempts instead of silently producing a Config that never
+// retries (Publisher.Do's loop is a no-op when MaxAttempts <= 0).
+func TestNewConfigFromEnvRejectsNonPositiveMaxAttempts(t *testing.T) {
+	for _, v := range []string{"0", "-1", "not-a-number"} {
+		t.Setenv("BACKEND_MAX_ATTEMPTS", v)
+		if cfg := NewConfigFromEnv(); cfg.MaxAttempts != defaultMaxAttempts {
+			t.Fatalf("BACKEND_MAX_ATTEMPTS=%q: expected fallback to defaultMaxAttempts (%d), got %d", v, defaultMaxAttempts, cfg.MaxAttempts)
+		}
+	}
+}
+
+func TestNewConfigFromEnvAcceptsPositiveMaxAttempts(t *testing.T) {
+	t.Setenv("BACKEND_MAX_ATTEMPTS", "5")
+	if cfg := NewConfigFromEnv(); cfg.MaxAttempts != 5 {
+		t.Fatalf("expected MaxAttempts 5, got %d", cfg.MaxAttempts)
+	}
+}
@@ -0,0 +1,210 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/simplelru"
+)
+
+// dedupEntry tracks how many times a record has been suppressed since it
+// was first seen within the current window. inner is the specific
+// WithAttrs/WithGroup-derived handler that produced the record, so a
+// flushed follow-up is emitted through the same bound attrs/group chain
+// instead of a single handler shared by every derivation.
+type dedupEntry struct {
+	firstSeen   time.Time
+	repeatCount int
+	record      slog.Record
+	inner       slog.Handler
+}
+
+// dedupState is the mutable state a DedupHandler shares with every handler
+// derived from it via WithAttrs/WithGroup, so dedup applies consistently
+// across a family of derived loggers rather than per-derivation.
+type dedupState struct {
+	mu      sync.Mutex
+	entries *simplelru.LRU[uint64, *dedupEntry]
+	stop    chan struct{}
+}
+
+// DedupHandler wraps an inner slog.Handler and collapses identical
+// records - same level, message, bound attrs, and sorted inline
+// attributes, hashed together - seen within window into a single record
+// carrying a repeat_count attribute. It is safe for concurrent use.
+type DedupHandler struct {
+	inner  slog.Handler
+	window time.Duration
+	state  *dedupState
+	// boundAttrs are the attrs this specific handler was derived with via
+	// WithAttrs, group-qualified by groupPrefix, folded into the dedup key
+	// so two loggers bound with different attrs never collapse together.
+	boundAttrs  []slog.Attr
+	groupPrefix string
+}
+
+// NewDedupHandler wraps inner with a dedup layer bounded to size distinct
+// record keys, collapsing repeats of the same record seen within window
+// into one, with a repeat_count attribute noting how many were
+// suppressed. Suppressed counts flush either when a record for that key
+// arrives after window has elapsed, when the periodic sweep finds window
+// elapsed without one, or when the key is evicted from the bounded LRU.
+func NewDedupHandler(inner slog.Handler, window time.Duration, size int) *DedupHandler {
+	state := &dedupState{stop: make(chan struct{})}
+	h := &DedupHandler{inner: inner, window: window, state: state}
+
+	state.entries, _ = simplelru.NewLRU[uint64, *dedupEntry](size, func(_ uint64, entry *dedupEntry) {
+		flush(state, entry)
+	})
+
+	go h.sweep()
+
+	return h
+}
+
+func (h *DedupHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+func (h *DedupHandler) Handle(ctx context.Context, record slog.Record) error {
+	key := hashRecord(record, h.boundAttrs)
+	state := h.state
+
+	state.mu.Lock()
+	if entry, ok := state.entries.Get(key); ok && time.Since(entry.firstSeen) < h.window {
+		entry.repeatCount++
+		state.mu.Unlock()
+		return nil
+	} else if ok {
+		// * Window elapsed since this key was first seen; flush what was
+		// * suppressed before starting a fresh window.
+		flush(state, entry)
+	}
+	state.entries.Add(key, &dedupEntry{firstSeen: time.Now(), record: record, inner: h.inner})
+	state.mu.Unlock()
+
+	return h.inner.Handle(ctx, record)
+}
+
+func (h *DedupHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	qualified := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		if h.groupPrefix != "" {
+			a.Key = h.groupPrefix + a.Key
+		}
+		qualified[i] = a
+	}
+
+	bound := make([]slog.Attr, 0, len(h.boundAttrs)+len(qualified))
+	bound = append(bound, h.boundAttrs...)
+	bound = append(bound, qualified...)
+
+	return &DedupHandler{
+		inner:       h.inner.WithAttrs(attrs),
+		window:      h.window,
+		state:       h.state,
+		boundAttrs:  bound,
+		groupPrefix: h.groupPrefix,
+	}
+}
+
+func (h *DedupHandler) WithGroup(name string) slog.Handler {
+	return &DedupHandler{
+		inner:       h.inner.WithGroup(name),
+		window:      h.window,
+		state:       h.state,
+		boundAttrs:  h.boundAttrs,
+		groupPrefix: h.groupPrefix + name + ".",
+	}
+}
+
+// Close stops the background sweep goroutine. The handler must not be
+// used after Close.
+func (h *DedupHandler) Close() {
+	close(h.state.stop)
+}
+
+// sweep periodically flushes entries whose window has elapsed without a
+// new matching record arriving to trigger the flush from Handle.
+func (h *DedupHandler) sweep() {
+	ticker := time.NewTicker(h.window)
+	defer ticker.Stop()
+
+	state := h.state
+	for {
+		select {
+		case <-ticker.C:
+			state.mu.Lock()
+			for _, key := range state.entries.Keys() {
+				entry, ok := state.entries.Peek(key)
+				if !ok || time.Since(entry.firstSeen) < h.window || entry.repeatCount == 0 {
+					continue
+				}
+				flush(state, entry)
+				entry.repeatCount = 0
+				entry.firstSeen = time.Now()
+			}
+			state.mu.Unlock()
+		case <-state.stop:
+			return
+		}
+	}
+}
+
+// flush emits a follow-up record noting how many times the original was
+// suppressed, through the same bound inner handler the suppressed records
+// were addressed to. Callers must hold state.mu.
+func flush(state *dedupState, entry *dedupEntry) {
+	if entry.repeatCount == 0 {
+		return
+	}
+	record := entry.record.Clone()
+	record.AddAttrs(slog.Int("repeat_count", entry.repeatCount))
+	_ = entry.inner.Handle(context.Background(), record)
+}
+
+// hashRecord derives a stable key for record from its level, message, and
+// sorted attributes - both the inline attrs passed to Debug/Info/... and
+// boundAttrs bound earlier via WithAttrs - so that unordered-but-identical
+// attribute sets hash the same, and two loggers bound with different
+// attrs never collapse into one another.
+func hashRecord(record slog.Record, boundAttrs []slog.Attr) uint64 {
+	var b strings.Builder
+	b.WriteString(record.Level.String())
+	b.WriteByte('|')
+	b.WriteString(record.Message)
+
+	attrs := make([]string, 0, record.NumAttrs()+len(boundAttrs))
+	for _, a := range boundAttrs {
+		attrs = append(attrs, fmt.Sprintf("%s=%v", a.Key, a.Value))
+	}
+	record.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, fmt.Sprintf("%s=%v", a.Key, a.Value))
+		return true
+	})
+	sort.Strings(attrs)
+
+	for _, a := range attrs {
+		b.WriteByte('|')
+		b.WriteString(a)
+	}
+
+	return fnv1a(b.String())
+}
+
+func fnv1a(s string) uint64 {
+	const offsetBasis uint64 = 14695981039346656037
+	const prime uint64 = 1099511628211
+
+	hash := offsetBasis
+	for i := 0; i < len(s); i++ {
+		hash ^= uint64(s[i])
+		hash *= prime
+	}
+	return hash
+}
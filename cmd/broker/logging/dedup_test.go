@@ -0,0 +1,185 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingHandler records every record handed to it so tests can assert on
+// how many actually made it through the dedup layer.
+type countingHandler struct {
+	mu      sync.Mutex
+	records []slog.Record
+}
+
+func (h *countingHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *countingHandler) Handle(_ context.Context, record slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, record)
+	return nil
+}
+
+func (h *countingHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h *countingHandler) WithGroup(string) slog.Handler      { return h }
+
+func (h *countingHandler) count() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.records)
+}
+
+// attrHandler, unlike countingHandler, actually honors WithAttrs: bound
+// attrs are folded onto every record it handles. This is what lets
+// TestDedupHandlerDistinguishesBoundAttrs and
+// TestDedupHandlerFlushesThroughBoundInner catch bugs countingHandler's
+// no-op WithAttrs can't.
+type attrHandler struct {
+	mu      *sync.Mutex
+	records *[]slog.Record
+	attrs   []slog.Attr
+}
+
+func newAttrHandler() *attrHandler {
+	return &attrHandler{mu: &sync.Mutex{}, records: &[]slog.Record{}}
+}
+
+func (h *attrHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *attrHandler) Handle(_ context.Context, record slog.Record) error {
+	record.AddAttrs(h.attrs...)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	*h.records = append(*h.records, record)
+	return nil
+}
+
+func (h *attrHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	bound := append(append([]slog.Attr{}, h.attrs...), attrs...)
+	return &attrHandler{mu: h.mu, records: h.records, attrs: bound}
+}
+
+func (h *attrHandler) WithGroup(string) slog.Handler { return h }
+
+func (h *attrHandler) recordsSnapshot() []slog.Record {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := make([]slog.Record, len(*h.records))
+	copy(out, *h.records)
+	return out
+}
+
+func hasAttr(record slog.Record, key string) bool {
+	found := false
+	record.Attrs(func(a slog.Attr) bool {
+		if a.Key == key {
+			found = true
+		}
+		return true
+	})
+	return found
+}
+
+func TestDedupHandlerCollapsesDuplicatesWithinWindow(t *testing.T) {
+	inner := &countingHandler{}
+	h := NewDedupHandler(inner, time.Hour, 16)
+	defer h.Close()
+
+	logger := slog.New(h)
+	for i := 0; i < 5; i++ {
+		logger.Info("connection reset", "peer", "10.0.0.1")
+	}
+
+	if got := inner.count(); got != 1 {
+		t.Fatalf("expected only the first occurrence to pass through, got %d records", got)
+	}
+}
+
+func TestDedupHandlerPassesDistinctRecords(t *testing.T) {
+	inner := &countingHandler{}
+	h := NewDedupHandler(inner, time.Hour, 16)
+	defer h.Close()
+
+	logger := slog.New(h)
+	logger.Info("connection reset", "peer", "10.0.0.1")
+	logger.Info("connection reset", "peer", "10.0.0.2")
+	logger.Warn("connection reset", "peer", "10.0.0.1")
+
+	if got := inner.count(); got != 3 {
+		t.Fatalf("expected distinct level/message/attrs combinations to all pass through, got %d records", got)
+	}
+}
+
+func TestDedupHandlerFlushesAfterWindowElapses(t *testing.T) {
+	inner := &countingHandler{}
+	h := NewDedupHandler(inner, 10*time.Millisecond, 16)
+	defer h.Close()
+
+	logger := slog.New(h)
+	logger.Info("connection reset", "peer", "10.0.0.1")
+	logger.Info("connection reset", "peer", "10.0.0.1")
+
+	time.Sleep(50 * time.Millisecond)
+
+	logger.Info("connection reset", "peer", "10.0.0.1")
+
+	if got := inner.count(); got < 2 {
+		t.Fatalf("expected the suppressed repeat and the post-window record to both pass through, got %d records", got)
+	}
+}
+
+// TestDedupHandlerDistinguishesBoundAttrs asserts that two loggers derived
+// from the same root via WithAttrs with different bound values never
+// collapse into a single record, even when logging a fixed message with no
+// inline attrs (e.g. the /health handler's "Creating health check
+// response").
+func TestDedupHandlerDistinguishesBoundAttrs(t *testing.T) {
+	inner := newAttrHandler()
+	root := NewDedupHandler(inner, time.Hour, 16)
+	defer root.Close()
+
+	alice := slog.New(root.WithAttrs([]slog.Attr{slog.String("userId", "alice")}))
+	bob := slog.New(root.WithAttrs([]slog.Attr{slog.String("userId", "bob")}))
+
+	alice.Debug("Creating health check response")
+	bob.Debug("Creating health check response")
+
+	if got := len(inner.recordsSnapshot()); got != 2 {
+		t.Fatalf("expected distinct bound attrs to produce distinct records, got %d", got)
+	}
+}
+
+// TestDedupHandlerFlushesThroughBoundInner asserts that a flushed
+// repeat_count follow-up is emitted through the bound handler that
+// produced the suppressed entries, not a bare root handler that would
+// drop the bound attrs.
+func TestDedupHandlerFlushesThroughBoundInner(t *testing.T) {
+	inner := newAttrHandler()
+	root := NewDedupHandler(inner, 10*time.Millisecond, 16)
+	defer root.Close()
+
+	alice := slog.New(root.WithAttrs([]slog.Attr{slog.String("userId", "alice")}))
+	alice.Debug("connection reset")
+	alice.Debug("connection reset")
+
+	time.Sleep(50 * time.Millisecond)
+	alice.Debug("connection reset")
+
+	var sawFlush bool
+	for _, record := range inner.recordsSnapshot() {
+		if !hasAttr(record, "repeat_count") {
+			continue
+		}
+		sawFlush = true
+		if !hasAttr(record, "userId") {
+			t.Fatalf("flushed repeat_count record is missing the bound userId attr - emitted through the wrong inner handler")
+		}
+	}
+	if !sawFlush {
+		t.Fatalf("expected a flushed repeat_count record")
+	}
+}
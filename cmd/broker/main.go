@@ -1,27 +1,24 @@
 package main
 
 import (
+	"context"
 	"os"
 	"strconv"
 	"time"
 
+	"github.com/alexchomiak/broker/cmd/broker/cache"
+	"github.com/alexchomiak/broker/cmd/broker/logging"
 	"github.com/alexchomiak/broker/cmd/broker/metric"
 	"github.com/alexchomiak/broker/cmd/broker/model"
+	"github.com/alexchomiak/broker/cmd/broker/ratelimit"
 	"github.com/alexchomiak/broker/cmd/broker/request"
+	"github.com/alexchomiak/broker/cmd/broker/telemetry"
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/limiter"
 	"github.com/gofiber/fiber/v2/middleware/pprof"
 	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"github.com/google/uuid"
-	"github.com/hashicorp/golang-lru/v2/simplelru"
-	"github.com/openzipkin/zipkin-go"
-	zipkinmodel "github.com/openzipkin/zipkin-go/model"
-	reporterhttp "github.com/openzipkin/zipkin-go/reporter/http"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
-	"github.com/valyala/fasthttp"
-	"github.com/valyala/fasthttp/fasthttpadaptor"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -44,6 +41,7 @@ func main() {
 	}))
 
 	metricPublisher := metric.NewMetricPublisher()
+	baseLogger := logging.NewFromEnv(log)
 
 	startup.Info("Initializing broker service")
 	app := fiber.New()
@@ -57,14 +55,25 @@ func main() {
 		port = "8080"
 	}
 
-	// * Install prom metrics handler
-	var metricsHandler fasthttp.RequestHandler
-	metricsHandler = fasthttpadaptor.NewFastHTTPHandler(promhttp.Handler())
-
-	app.Get("/metrics", func(c *fiber.Ctx) error {
-		metricsHandler(c.Context())
-		return nil
-	})
+	startup.Info("Initializing telemetry provider")
+	telemetryProvider, err := telemetry.NewProviderFromEnv(log)
+	if err != nil {
+		log.Fatal("Error initializing telemetry provider", zap.Error(err))
+	}
+	defer telemetryProvider.Shutdown(context.Background())
+
+	// * Install metrics scrape handler, if the backend exposes one. OTLP
+	// * pushes metrics to the collector instead, so there is nothing to
+	// * scrape in that mode. Gather metricPublisher's own registry alongside
+	// * prometheus.DefaultGatherer so ad-hoc promauto metrics (like
+	// * opsProcessed below) still show up on the same endpoint.
+	gatherer := prometheus.Gatherers{metricPublisher.Gatherer(), prometheus.DefaultGatherer}
+	if metricsHandler := telemetryProvider.MetricsHandler(gatherer); metricsHandler != nil {
+		app.Get("/metrics", func(c *fiber.Ctx) error {
+			metricsHandler(c.Context())
+			return nil
+		})
+	}
 
 	opsProcessed := promauto.NewCounter(prometheus.CounterOpts{
 		Name: "myapp_processed_ops_total",
@@ -76,121 +85,57 @@ func main() {
 	// * Install PPROF middleware for profiling
 	app.Use(pprof.New())
 
-	app.Use(limiter.New(limiter.Config{
-		Max:               30,
-		Expiration:        1 * time.Minute,
-		LimiterMiddleware: limiter.SlidingWindow{},
-		LimitReached: func(c *fiber.Ctx) error {
-			return c.JSON(&model.ErrorResponse{
-				TimeStamp: time.Now(),
-				Message:   "Too many requests. Maybe you should slow down? 🤓",
-			})
-		},
+	// * The sliding-window counter is kept in RATE_LIMIT_BACKEND (memory by
+	// * default, redis to share the 30 req/min ceiling across instances).
+	app.Use(ratelimit.New(ratelimit.Config{
+		Max:     30,
+		Window:  1 * time.Minute,
+		Backend: ratelimit.NewBackendFromEnv(),
+		Metrics: metricPublisher,
 	}))
 
-	// * Install ZIPKIN middleware for tracing
-	// zipkinUrl := os.Getenv("ZIPKIN_ENDPOINT")
-	// zipkinServiceName := os.Getenv("ZIPKIN_SERVICE_NAME")
-	// zipkinServicePort := os.Getenv("ZIPKIN_SERVICE_HOST")
-
-	log.Info("Initializing Zipkin Tracer")
-	endpointUrl := "http://localhost:9411/api/v2/spans"
-	localEndpoint, zerr := zipkin.NewEndpoint("broker", "localhost:9411")
-	if zerr != nil {
-		log.Fatal("Error initializing Zipkin Tracer", zap.Error(zerr))
-	}
-	reporter := reporterhttp.NewReporter(endpointUrl)
-	sampler, zerr := zipkin.NewCountingSampler(1.0)
-	if zerr != nil {
-		log.Fatal("Error initializing Zipkin Tracer", zap.Error(zerr))
-	}
-
-	trace, zerr := zipkin.NewTracer(
-		reporter,
-		zipkin.WithLocalEndpoint(localEndpoint),
-		zipkin.WithSampler(sampler),
-	)
-	if zerr != nil {
-		log.Fatal("Error initializing Zipkin Tracer", zap.Error(zerr))
-	}
-
-	app.Use(func(c *fiber.Ctx) error {
-		// * Start Span
-		rspan, ctx := trace.StartSpanFromContext(c.UserContext(), c.Path(), zipkin.Kind(zipkinmodel.Server))
-		c.Locals("tracer", trace)
-		c.Locals("parentSpan", rspan)
-		c.SetUserContext(ctx)
-		c.Next()
-		rspan.Finish()
-		return nil
-	})
+	// * Install telemetry middleware for tracing. The concrete backend
+	// * (Zipkin+Prometheus or OpenTelemetry OTLP) is selected by
+	// * TELEMETRY_BACKEND; handlers only ever see the request.Tracer/
+	// * request.Span abstractions.
+	app.Use(telemetryProvider.Middleware())
 
 	app.Use(requestid.New())
 
-	// * Example of instance memory user cache
-	// * using GO LRU to cache a uuid based off user IP
-	// * though, this could be used to cache DB calls for User objects/etc
-	// ! Note: when scaling this across multiple instances,
-	// ! this cache will not be shared across instances
-	// ! So it is best to use a Network load balancer/sticky sessions
-	// ! or a shared cache like Redis
-	cacheSizeStr := os.Getenv("CACHE_SIZE")
-	var cacheSize int
-	if cacheSizeStr != "" {
-		cacheSizeInt64, _ := strconv.ParseInt(cacheSizeStr, 10, 64)
-		cacheSize = int(cacheSizeInt64)
-	} else {
-		cacheSize = 100
+	// * Cache a uuid based off user IP, so it could be used to cache DB
+	// * calls for User objects/etc. Backed by CACHE_BACKEND=memory|redis|
+	// * tiered; memory (the default) is not shared across instances, so
+	// * redis or tiered is best once this is scaled past one instance.
+	userCache, err := cache.NewFromEnv(metricPublisher)
+	if err != nil {
+		log.Fatal("Error initializing user cache", zap.Error(err))
 	}
 
-	userCache, _ := simplelru.NewLRU[string, string](int(cacheSize), nil)
 	app.Use(func(c *fiber.Ctx) error {
-		userSpan, _ := request.GetTracer(c).StartSpanFromContext(c.UserContext(), "resolveUser")
+		userSpan, spanCtx := request.GetTracer(c).StartSpanFromContext(c.UserContext(), "resolveUser")
 		ip := c.IP()
-		var userId string
-		if userCache.Contains(ip) {
-			userId, _ = userCache.Get(ip)
-		} else {
+
+		userId, ok, err := userCache.Get(spanCtx, ip)
+		if err != nil || !ok {
 			userId = uuid.New().String()
-			userCache.Add(ip, userId)
+			_ = userCache.Set(spanCtx, ip, userId)
 		}
+
 		c.Locals("userId", userId)
 		userSpan.Finish()
 		return c.Next()
 	})
 
-	// * Logging Middleware using Zap
+	// * Logging Middleware
 	app.Use(func(c *fiber.Ctx) error {
 		createLoggerSpan, _ := request.GetTracer(c).StartSpanFromContext(c.UserContext(), "createLogger")
 
-		logger := log.WithOptions(
-			zap.Fields(
-				zap.Field{
-					Key:    "RequestID",
-					Type:   zapcore.StringType,
-					String: c.Locals("requestid").(string),
-				},
-				zap.Field{
-					Key:    "method",
-					Type:   zapcore.StringType,
-					String: c.Method(),
-				},
-				zap.Field{
-					Key:    "path",
-					Type:   zapcore.StringType,
-					String: c.Path(),
-				},
-				zap.Field{
-					Key:    "scope",
-					Type:   zapcore.StringType,
-					String: "client-request",
-				},
-				zap.Field{
-					Key:    "userId",
-					Type:   zapcore.StringType,
-					String: c.Locals("userId").(string),
-				},
-			),
+		logger := baseLogger.With(
+			"RequestID", c.Locals("requestid").(string),
+			"method", c.Method(),
+			"path", c.Path(),
+			"scope", "client-request",
+			"userId", c.Locals("userId").(string),
 		)
 
 		c.Locals("logger", logger)
@@ -201,7 +146,7 @@ func main() {
 
 		infoSpan, _ := request.GetTracer(c).StartSpanFromContext(c.UserContext(), "infoLog")
 
-		logger.Info("Request Started", zap.Time("startTime", startTime))
+		logger.Info("Request Started", "startTime", startTime)
 		infoSpan.Finish()
 		c.Next()
 		opsProcessed.Inc()
@@ -211,16 +156,16 @@ func main() {
 		metricPublisher.PublishHistogram(metric.HttpRequestDurationMicroMetricName, c, float64(time.Since(startTime).Microseconds()))
 
 		logger.Info("Request Completed",
-			zap.Int64("timeElapsedMillis", time.Since(startTime).Milliseconds()),
-			zap.Int64("timeElapsedMicros", time.Since(startTime).Microseconds()),
+			"timeElapsedMillis", time.Since(startTime).Milliseconds(),
+			"timeElapsedMicros", time.Since(startTime).Microseconds(),
 		)
 
 		return nil
 	})
 
 	app.Use(func(c *fiber.Ctx) error {
-		// * Set Zipkin Parent Span tags
-		span := c.Locals("parentSpan").(zipkin.Span)
+		// * Set root span tags
+		span := request.GetSpan(c)
 		span.Tag("userId", c.Locals("userId").(string))
 		span.Tag("requestId", c.Locals("requestid").(string))
 		span.Tag("method", c.Method())
@@ -259,7 +204,7 @@ func main() {
 	metricPublisher.Initialize(app)
 
 	startup.Info("Starting up the broker service")
-	err := app.Listen(":" + port)
+	err = app.Listen(":" + port)
 	if err != nil {
 		startup.Error("Error starting up the broker service", err)
 	}
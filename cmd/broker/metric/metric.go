@@ -2,7 +2,9 @@ package metric
 
 import (
 	"fmt"
-	"regexp"
+	"sort"
+	"strconv"
+	"sync"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/prometheus/client_golang/prometheus"
@@ -16,74 +18,211 @@ const (
 	HttpRequestDurationMicroMetricName = "http_request_duration_micro"
 )
 
+// * defaultBuckets mirrors the buckets the per-route histograms used to be
+// * registered with before label vectors replaced them, scaled for
+// * millisecond-denominated observations.
+var defaultBuckets = []float64{1, 2, 4, 8, 10, 25, 50, 100, 250, 500, 1000}
+
+// * microBuckets covers the same relative range as defaultBuckets, scaled
+// * up by 1000x for metrics observed in microseconds rather than
+// * milliseconds (e.g. HttpRequestDurationMicroMetricName).
+var microBuckets = []float64{1000, 2000, 4000, 8000, 10000, 25000, 50000, 100000, 250000, 500000, 1000000}
+
+// * namedBuckets lets a specific metric name opt into a bucket set other
+// * than defaultBuckets, keyed by the unit its observations are actually
+// * in.
+var namedBuckets = map[string][]float64{
+	HttpRequestDurationMicroMetricName: microBuckets,
+}
+
+// bucketsFor returns the Buckets a HistogramVec registered under name
+// should use, falling back to defaultBuckets for any name without an
+// entry in namedBuckets.
+func bucketsFor(name string) []float64 {
+	if buckets, ok := namedBuckets[name]; ok {
+		return buckets
+	}
+	return defaultBuckets
+}
+
+// * quantileObjectives feeds every SummaryVec this package creates, giving
+// * p50/p90/p95/p99 (analogous to go-kit's TimeHistogram/Summary pattern)
+// * with go-kit's default rendezvous error tolerances.
+var quantileObjectives = map[float64]float64{
+	0.5:  0.05,
+	0.9:  0.01,
+	0.95: 0.005,
+	0.99: 0.001,
+}
+
+// * vecLabels is shared by every Counter/Histogram/Summary vector this
+// * package publishes so PromQL can aggregate across routes instead of
+// * across metric families.
+var vecLabels = []string{"method", "route", "status"}
+
+// MetricPublisher lazily registers and publishes labeled Prometheus vectors
+// for HTTP request metrics. Unlike the old per-route Counter/Histogram
+// pairs, a single CounterVec/HistogramVec/SummaryVec is registered per
+// metric name and requests are distinguished by `method`, `route`, and
+// `status` labels instead of mangling the route into the metric name.
+//
+// Each MetricPublisher owns its own prometheus.Registry rather than
+// registering against prometheus.DefaultRegisterer, so a second
+// MetricPublisher publishing under the same metric name (another test
+// case, a future second instance) never collides with this one's
+// collectors. Gatherer exposes that registry for whatever serves /metrics.
 type MetricPublisher struct {
-	metricNameRegex *regexp.Regexp
-	metricMap       map[string]any
+	mu          sync.Mutex
+	reg         *prometheus.Registry
+	counterVecs map[string]*prometheus.CounterVec
+	histoVecs   map[string]*prometheus.HistogramVec
+	summaryVecs map[string]*prometheus.SummaryVec
 }
 
 func NewMetricPublisher() *MetricPublisher {
 	return &MetricPublisher{
-		metricNameRegex: regexp.MustCompile("[^_A-Za-z]+"),
-		metricMap:       make(map[string]any),
+		reg:         prometheus.NewRegistry(),
+		counterVecs: make(map[string]*prometheus.CounterVec),
+		histoVecs:   make(map[string]*prometheus.HistogramVec),
+		summaryVecs: make(map[string]*prometheus.SummaryVec),
 	}
 }
 
-func (mc *MetricPublisher) GetMetricName(ctx *fiber.Ctx) string {
-	return mc.metricNameRegex.ReplaceAllString(
-		fmt.Sprintf("%s_%s", ctx.Method(), ctx.Path()),
-		"",
-	)
+// Gatherer exposes mc's own registry so a /metrics scrape handler can
+// gather from it (typically merged with prometheus.DefaultGatherer via
+// prometheus.Gatherers, to also pick up metrics registered elsewhere in
+// the process).
+func (mc *MetricPublisher) Gatherer() prometheus.Gatherer {
+	return mc.reg
 }
 
-func (mc *MetricPublisher) PublishCounter(prefix string, ctx *fiber.Ctx) {
-	key := fmt.Sprintf("%s_%s", prefix, mc.GetMetricName(ctx))
-	m, ok := mc.metricMap[key]
+// * labelsFor derives the {method, route, status} label set for a request.
+// * It uses ctx.Route().Path (the matched route template, e.g.
+// * "/users/:id") rather than ctx.Path() so parameterized routes don't
+// * explode label cardinality with concrete path values.
+func labelsFor(ctx *fiber.Ctx) prometheus.Labels {
+	return prometheus.Labels{
+		"method": ctx.Method(),
+		"route":  ctx.Route().Path,
+		"status": strconv.Itoa(ctx.Response().StatusCode()),
+	}
+}
+
+func (mc *MetricPublisher) counterVec(name string) *prometheus.CounterVec {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	vec, ok := mc.counterVecs[name]
 	if !ok {
-		return
+		vec = promauto.With(mc.reg).NewCounterVec(prometheus.CounterOpts{
+			Name: name,
+			Help: fmt.Sprintf("Count of %s events, labeled by method/route/status", name),
+		}, vecLabels)
+		mc.counterVecs[name] = vec
 	}
-	m.(prometheus.Counter).Inc()
+	return vec
 }
 
-func (mc *MetricPublisher) GetHistogram(prefix string, ctx *fiber.Ctx, value float64) {
-	key := fmt.Sprintf("%s_%s", prefix, mc.GetMetricName(ctx))
-	m, ok := mc.metricMap[key]
+func (mc *MetricPublisher) histogramVec(name string) *prometheus.HistogramVec {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	vec, ok := mc.histoVecs[name]
+	if !ok {
+		vec = promauto.With(mc.reg).NewHistogramVec(prometheus.HistogramOpts{
+			Name:    name,
+			Help:    fmt.Sprintf("Distribution of %s observations, labeled by method/route/status", name),
+			Buckets: bucketsFor(name),
+		}, vecLabels)
+		mc.histoVecs[name] = vec
+	}
+	return vec
+}
+
+func (mc *MetricPublisher) summaryVec(name string) *prometheus.SummaryVec {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	vec, ok := mc.summaryVecs[name]
 	if !ok {
-		return
+		vec = promauto.With(mc.reg).NewSummaryVec(prometheus.SummaryOpts{
+			Name:       name + "_summary",
+			Help:       fmt.Sprintf("Quantiles (p50/p90/p95/p99) of %s observations, labeled by method/route/status", name),
+			Objectives: quantileObjectives,
+		}, vecLabels)
+		mc.summaryVecs[name] = vec
 	}
-	m.(prometheus.Histogram).Observe(value)
+	return vec
 }
 
-func (mc *MetricPublisher) InsertHistogram(
-	metricName string,
-	metricDescription string,
-	buckets []float64,
-) {
-	mc.metricMap[metricName] = promauto.NewHistogram(prometheus.HistogramOpts{
-		Name:    metricName,
-		Help:    metricDescription,
-		Buckets: buckets,
-	})
+// PublishCounter increments the CounterVec registered under prefix,
+// registering it lazily on first use. Labels are derived from ctx.
+func (mc *MetricPublisher) PublishCounter(prefix string, ctx *fiber.Ctx) {
+	mc.counterVec(prefix).With(labelsFor(ctx)).Inc()
 }
 
-func (mc *MetricPublisher) InsertCounter(metricName string, metricDescription string) {
-	mc.metricMap[metricName] = promauto.NewCounter(prometheus.CounterOpts{
-		Name: metricName,
-		Help: metricDescription,
-	})
+// PublishHistogram observes value against both the HistogramVec and the
+// companion SummaryVec (for p50/p90/p95/p99) registered under prefix,
+// registering either lazily on first use. Labels are derived from ctx.
+func (mc *MetricPublisher) PublishHistogram(prefix string, ctx *fiber.Ctx, value float64) {
+	labels := labelsFor(ctx)
+	mc.histogramVec(prefix).With(labels).Observe(value)
+	mc.summaryVec(prefix).With(labels).Observe(value)
 }
 
-func (mc *MetricPublisher) Initialize(app *fiber.App) {
-	routes := app.GetRoutes()
-	for _, route := range routes {
-		key := route.Method + "_" + route.Path
-		postfix := mc.metricNameRegex.ReplaceAllString(key, "")
-
-		// * Install route metrics
-		mc.InsertCounter(fmt.Sprintf("%s_%s", HttpRequestCountMetricName, postfix), fmt.Sprintf("Number of HTTP requests for %s", key))
-		mc.InsertHistogram(
-			fmt.Sprintf("%s_%s", HttpRequestDurationMetricName, postfix),
-			fmt.Sprintf("Duration of HTTP requests for %s", key),
-			[]float64{1, 2, 4, 8, 10, 25, 50, 100, 250, 500, 1000},
-		)
+// PublishLabeledCounter increments the counter registered under name for
+// the given labels, registering it lazily on first use with those labels'
+// keys. Unlike PublishCounter, labels are caller-supplied instead of
+// derived from an HTTP request, for instrumenting non-HTTP operations
+// (e.g. outbound client attempts) that don't have a method/route/status
+// to key on.
+func (mc *MetricPublisher) PublishLabeledCounter(name string, labels map[string]string) {
+	mc.mu.Lock()
+	vec, ok := mc.counterVecs[name]
+	if !ok {
+		keys := make([]string, 0, len(labels))
+		for k := range labels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		vec = promauto.With(mc.reg).NewCounterVec(prometheus.CounterOpts{
+			Name: name,
+			Help: fmt.Sprintf("Count of %s events", name),
+		}, keys)
+		mc.counterVecs[name] = vec
 	}
+	mc.mu.Unlock()
+
+	vec.With(prometheus.Labels(labels)).Inc()
 }
+
+// PublishLabeledHistogram observes value against the histogram registered
+// under name for the given labels, registering it lazily on first use
+// with those labels' keys. Like PublishLabeledCounter, this is for
+// instrumenting non-HTTP operations (e.g. cache round-trip latency).
+func (mc *MetricPublisher) PublishLabeledHistogram(name string, labels map[string]string, value float64) {
+	mc.mu.Lock()
+	vec, ok := mc.histoVecs[name]
+	if !ok {
+		keys := make([]string, 0, len(labels))
+		for k := range labels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		vec = promauto.With(mc.reg).NewHistogramVec(prometheus.HistogramOpts{
+			Name:    name,
+			Help:    fmt.Sprintf("Distribution of %s observations", name),
+			Buckets: bucketsFor(name),
+		}, keys)
+		mc.histoVecs[name] = vec
+	}
+	mc.mu.Unlock()
+
+	vec.With(prometheus.Labels(labels)).Observe(value)
+}
+
+// Initialize is retained for backwards compatibility with existing call
+// sites. Vector registration is now lazy on first PublishCounter/
+// PublishHistogram call, so there is no longer any per-route enumeration
+// to perform here.
+func (mc *MetricPublisher) Initialize(app *fiber.App) {}
@@ -0,0 +1,140 @@
+package metric
+
+import (
+	"io"
+	"math"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func newTestApp(mc *MetricPublisher) *fiber.App {
+	app := fiber.New()
+
+	app.Get("/users/:id", func(c *fiber.Ctx) error {
+		c.Status(200)
+		mc.PublishCounter(HttpRequestCountMetricName, c)
+		mc.PublishHistogram(HttpRequestDurationMetricName, c, 12)
+		return nil
+	})
+
+	app.Get("/ping", func(c *fiber.Ctx) error {
+		c.Status(200)
+		value, _ := strconv.ParseFloat(c.Query("v"), 64)
+		mc.PublishHistogram(HttpRequestDurationMetricName, c, value)
+		return nil
+	})
+
+	return app
+}
+
+func doGet(app *fiber.App, path string) {
+	req := httptest.NewRequest("GET", path, nil)
+	resp, err := app.Test(req)
+	if err != nil {
+		panic(err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+}
+
+// TestLabelCardinality asserts that requests to different concrete paths
+// matching the same route collapse into a single label series instead of
+// minting a new metric family per path, as the old name-mangling publisher
+// did.
+func TestLabelCardinality(t *testing.T) {
+	mc := NewMetricPublisher()
+	app := newTestApp(mc)
+
+	doGet(app, "/users/1")
+	doGet(app, "/users/2")
+	doGet(app, "/users/3")
+
+	vec := mc.counterVec(HttpRequestCountMetricName)
+
+	metric := &dto.Metric{}
+	counter, err := vec.GetMetricWithLabelValues("GET", "/users/:id", "200")
+	if err != nil {
+		t.Fatalf("unexpected error fetching counter: %v", err)
+	}
+	if err := counter.Write(metric); err != nil {
+		t.Fatalf("unexpected error writing metric: %v", err)
+	}
+
+	if got := metric.GetCounter().GetValue(); got != 3 {
+		t.Fatalf("expected a single series with value 3 for 3 requests to parameterized routes, got %v", got)
+	}
+
+	if len(mc.counterVecs) != 1 {
+		t.Fatalf("expected exactly one registered CounterVec regardless of path, got %d", len(mc.counterVecs))
+	}
+}
+
+// TestPathTemplateExtraction asserts that the route label uses the matched
+// route template rather than the concrete request path.
+func TestPathTemplateExtraction(t *testing.T) {
+	mc := NewMetricPublisher()
+	app := newTestApp(mc)
+
+	doGet(app, "/users/42")
+
+	metric := &dto.Metric{}
+	counter, err := mc.counterVec(HttpRequestCountMetricName).GetMetricWithLabelValues("GET", "/users/:id", "200")
+	if err != nil {
+		t.Fatalf("unexpected error fetching counter: %v", err)
+	}
+	if err := counter.Write(metric); err != nil {
+		t.Fatalf("unexpected error writing metric: %v", err)
+	}
+	if metric.GetCounter().GetValue() != 1 {
+		t.Fatalf("expected route template label \"/users/:id\" to have recorded the request")
+	}
+}
+
+// TestBucketsForSelectsByMetricName asserts that a metric with an entry in
+// namedBuckets (observed in a different unit than the rest) gets its own
+// bucket set rather than defaultBuckets.
+func TestBucketsForSelectsByMetricName(t *testing.T) {
+	micro := bucketsFor(HttpRequestDurationMicroMetricName)
+	if micro[len(micro)-1] <= defaultBuckets[len(defaultBuckets)-1] {
+		t.Fatalf("expected micro-duration buckets to be scaled above defaultBuckets, got %v", micro)
+	}
+
+	if got := bucketsFor(HttpRequestDurationMetricName); got[len(got)-1] != defaultBuckets[len(defaultBuckets)-1] {
+		t.Fatalf("expected a metric with no namedBuckets entry to fall back to defaultBuckets, got %v", got)
+	}
+}
+
+// TestSummaryQuantileAccuracy feeds a synthetic uniform workload of 1..1000
+// through PublishHistogram and asserts the resulting SummaryVec reports
+// quantiles within the configured error objectives.
+func TestSummaryQuantileAccuracy(t *testing.T) {
+	mc := NewMetricPublisher()
+	app := newTestApp(mc)
+
+	const samples = 1000
+	for i := 1; i <= samples; i++ {
+		doGet(app, "/ping?v="+strconv.Itoa(i))
+	}
+
+	metric := &dto.Metric{}
+	summary, err := mc.summaryVec(HttpRequestDurationMetricName).GetMetricWithLabelValues("GET", "/ping", "200")
+	if err != nil {
+		t.Fatalf("unexpected error fetching summary: %v", err)
+	}
+	if err := summary.(prometheus.Metric).Write(metric); err != nil {
+		t.Fatalf("unexpected error writing summary: %v", err)
+	}
+
+	for _, q := range metric.GetSummary().GetQuantile() {
+		expected := q.GetQuantile() * samples
+		tolerance := quantileObjectives[q.GetQuantile()]*samples + 1
+		if math.Abs(q.GetValue()-expected) > tolerance {
+			t.Fatalf("quantile p%.0f = %v outside tolerance of expected %v (+/-%v)", q.GetQuantile()*100, q.GetValue(), expected, tolerance)
+		}
+	}
+}
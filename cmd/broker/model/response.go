@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// ErrorResponse is the JSON body returned for a request the service refused
+// or failed to serve.
+type ErrorResponse struct {
+	TimeStamp time.Time `json:"timeStamp"`
+	Message   string    `json:"message"`
+}
+
+// HealthCheckResponse is the JSON body returned by the /health endpoint.
+type HealthCheckResponse struct {
+	TimeStamp time.Time `json:"timeStamp"`
+	Status    string    `json:"status"`
+}
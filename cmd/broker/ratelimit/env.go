@@ -0,0 +1,29 @@
+package ratelimit
+
+import (
+	"os"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// NewBackendFromEnv builds the Backend New's Config.Backend should use,
+// selected by RATE_LIMIT_BACKEND:
+//   - "memory" (default): process-local, not shared across instances.
+//   - "redis": distributed, shared across instances.
+func NewBackendFromEnv() Backend {
+	if os.Getenv("RATE_LIMIT_BACKEND") != "redis" {
+		return NewMemoryBackend()
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr: envOr("REDIS_ADDR", "localhost:6379"),
+	})
+	return NewRedisBackend(client, envOr("RATE_LIMIT_KEY_PREFIX", "broker:ratelimit:"))
+}
+
+func envOr(key, fallback string) string {
+	if v, ok := os.LookupEnv(key); ok {
+		return v
+	}
+	return fallback
+}
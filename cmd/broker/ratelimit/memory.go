@@ -0,0 +1,117 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/simplelru"
+)
+
+// defaultMemoryBackendSize bounds how many distinct keys MemoryBackend
+// tracks at once, so a churny NAT/IPv6 client population - or a trivial
+// IP-rotation attacker - can't grow its window map without bound.
+const defaultMemoryBackendSize = 10000
+
+// memoryWindow tracks the previous and current fixed-window counts for a
+// single key, plus the window length it was last seen with so sweep can
+// tell a stale entry from a live one.
+type memoryWindow struct {
+	bucket     int64
+	prev       int
+	curr       int
+	windowSecs int64
+}
+
+// MemoryBackend is a process-local sliding-window Backend. Like the fiber
+// limiter it replaces, its counters are not shared across instances; use
+// RedisBackend for that. windows is bounded by an LRU cap and swept
+// periodically, so an idle or rotated key doesn't linger in memory for the
+// life of the process.
+type MemoryBackend struct {
+	mu      sync.Mutex
+	windows *simplelru.LRU[string, *memoryWindow]
+	stop    chan struct{}
+}
+
+// NewMemoryBackend builds an empty MemoryBackend bounded to
+// defaultMemoryBackendSize distinct keys.
+func NewMemoryBackend() *MemoryBackend {
+	return NewMemoryBackendSize(defaultMemoryBackendSize)
+}
+
+// NewMemoryBackendSize builds an empty MemoryBackend bounded to size
+// distinct keys, sweeping windows more than one window length stale every
+// minute.
+func NewMemoryBackendSize(size int) *MemoryBackend {
+	windows, _ := simplelru.NewLRU[string, *memoryWindow](size, nil)
+	b := &MemoryBackend{windows: windows, stop: make(chan struct{})}
+	go b.sweep()
+	return b
+}
+
+func (b *MemoryBackend) Allow(_ context.Context, key string, max int, window time.Duration) (bool, error) {
+	windowSecs := int64(window / time.Second)
+	if windowSecs <= 0 {
+		windowSecs = 1
+	}
+
+	now := time.Now().Unix()
+	bucket := now / windowSecs
+	elapsed := now - bucket*windowSecs
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	w, ok := b.windows.Get(key)
+	switch {
+	case !ok || w.bucket < bucket-1:
+		// * No window yet, or the key has been idle longer than a full
+		// * window: start fresh with no carried-over previous count.
+		w = &memoryWindow{bucket: bucket}
+	case w.bucket == bucket-1:
+		// * Rolled into a new window: what was "current" becomes
+		// * "previous".
+		w = &memoryWindow{bucket: bucket, prev: w.curr}
+	}
+	w.curr++
+	w.windowSecs = windowSecs
+	b.windows.Add(key, w)
+
+	weighted := float64(w.prev)*(float64(windowSecs-elapsed)/float64(windowSecs)) + float64(w.curr)
+	return weighted <= float64(max), nil
+}
+
+// Close stops the background sweep goroutine. The backend must not be used
+// after Close.
+func (b *MemoryBackend) Close() {
+	close(b.stop)
+}
+
+// sweep periodically evicts windows more than one window length stale, so
+// an idle key doesn't sit in memory until LRU pressure from fresh keys
+// finally pushes it out.
+func (b *MemoryBackend) sweep() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			b.mu.Lock()
+			now := time.Now().Unix()
+			for _, key := range b.windows.Keys() {
+				w, ok := b.windows.Peek(key)
+				if !ok || w.windowSecs <= 0 {
+					continue
+				}
+				if now/w.windowSecs-w.bucket > 1 {
+					b.windows.Remove(key)
+				}
+			}
+			b.mu.Unlock()
+		case <-b.stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryBackendAllowsUpToMaxWithinWindow(t *testing.T) {
+	b := NewMemoryBackend()
+	defer b.Close()
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		allowed, err := b.Allow(ctx, "ip-1", 3, time.Minute)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !allowed {
+			t.Fatalf("request %d should have been allowed under max=3", i+1)
+		}
+	}
+
+	allowed, err := b.Allow(ctx, "ip-1", 3, time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if allowed {
+		t.Fatalf("the 4th request should have been denied under max=3")
+	}
+}
+
+func TestMemoryBackendKeysAreIndependent(t *testing.T) {
+	b := NewMemoryBackend()
+	defer b.Close()
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := b.Allow(ctx, "ip-1", 2, time.Minute); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	allowed, err := b.Allow(ctx, "ip-2", 2, time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !allowed {
+		t.Fatalf("a different key should not be affected by ip-1's usage")
+	}
+}
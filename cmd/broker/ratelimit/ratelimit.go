@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/alexchomiak/broker/cmd/broker/metric"
+	"github.com/alexchomiak/broker/cmd/broker/model"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RateLimitDecisionMetricName is the {outcome} labeled counter every
+// decision this middleware makes is recorded against.
+const RateLimitDecisionMetricName = "rate_limit_decision_count"
+
+// Backend implements the sliding-window counter algorithm: two adjacent
+// fixed windows of length window, combined as
+// prevCount*((window-elapsedInCurrent)/window) + currCount, atomically
+// incrementing the current window's counter as part of the decision.
+type Backend interface {
+	// Allow reports whether one more request for key is allowed under max
+	// requests per window.
+	Allow(ctx context.Context, key string, max int, window time.Duration) (bool, error)
+}
+
+// Config configures the rate-limit middleware built by New.
+type Config struct {
+	Max     int
+	Window  time.Duration
+	KeyFunc func(c *fiber.Ctx) string
+	Backend Backend
+	Metrics *metric.MetricPublisher
+}
+
+// New builds a fiber.Handler enforcing cfg's distributed sliding-window
+// limit via cfg.Backend, keyed by cfg.KeyFunc (defaulting to the request
+// IP, and cfg.Backend to an in-memory Backend, if unset).
+func New(cfg Config) fiber.Handler {
+	keyFunc := cfg.KeyFunc
+	if keyFunc == nil {
+		keyFunc = func(c *fiber.Ctx) string { return c.IP() }
+	}
+	backend := cfg.Backend
+	if backend == nil {
+		backend = NewMemoryBackend()
+	}
+
+	return func(c *fiber.Ctx) error {
+		allowed, err := backend.Allow(c.UserContext(), keyFunc(c), cfg.Max, cfg.Window)
+		if err != nil {
+			// * Fail open: a backend outage shouldn't take the whole
+			// * service down with it.
+			allowed = true
+		}
+
+		if cfg.Metrics != nil {
+			outcome := "deny"
+			if allowed {
+				outcome = "allow"
+			}
+			cfg.Metrics.PublishLabeledCounter(RateLimitDecisionMetricName, map[string]string{"outcome": outcome})
+		}
+
+		if !allowed {
+			c.Status(fiber.StatusTooManyRequests)
+			return c.JSON(&model.ErrorResponse{
+				TimeStamp: time.Now(),
+				Message:   "Too many requests. Maybe you should slow down? 🤓",
+			})
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingWindowScript implements the read+increment+decision atomically:
+// it increments the current fixed window's counter, then adds the previous
+// window's counter scaled by the fraction of the current window not yet
+// elapsed, so the combined count behaves like a sliding window
+// without needing a sorted set per request.
+var slidingWindowScript = redis.NewScript(`
+local prevKey = KEYS[1]
+local currKey = KEYS[2]
+local max = tonumber(ARGV[1])
+local windowSecs = tonumber(ARGV[2])
+local elapsed = tonumber(ARGV[3])
+
+local prevCount = tonumber(redis.call("GET", prevKey) or "0")
+local currCount = redis.call("INCR", currKey)
+if currCount == 1 then
+	redis.call("EXPIRE", currKey, windowSecs * 2)
+end
+
+local weighted = prevCount * ((windowSecs - elapsed) / windowSecs) + currCount
+if weighted > max then
+	return 0
+end
+return 1
+`)
+
+// RedisBackend is a distributed sliding-window Backend shared across
+// instances, backed by a single Lua script per decision so the
+// read+increment+decision is atomic.
+type RedisBackend struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisBackend builds a RedisBackend against client, prefixing every
+// key with prefix.
+func NewRedisBackend(client *redis.Client, prefix string) *RedisBackend {
+	return &RedisBackend{client: client, prefix: prefix}
+}
+
+func (b *RedisBackend) Allow(ctx context.Context, key string, max int, window time.Duration) (bool, error) {
+	windowSecs := int64(window / time.Second)
+	if windowSecs <= 0 {
+		windowSecs = 1
+	}
+
+	now := time.Now().Unix()
+	currBucket := now / windowSecs
+	prevBucket := currBucket - 1
+	elapsed := now - currBucket*windowSecs
+
+	currKey := fmt.Sprintf("%s%s:%d", b.prefix, key, currBucket)
+	prevKey := fmt.Sprintf("%s%s:%d", b.prefix, key, prevBucket)
+
+	allowed, err := slidingWindowScript.Run(ctx, b.client, []string{prevKey, currKey}, max, windowSecs, elapsed).Int()
+	if err != nil {
+		return false, err
+	}
+	return allowed == 1, nil
+}
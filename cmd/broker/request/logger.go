@@ -4,12 +4,26 @@ import (
 	"errors"
 
 	"github.com/gofiber/fiber/v2"
-	"go.uber.org/zap"
 )
 
-// * Returns reference to logger
-func GetLogger(ctx *fiber.Ctx) *zap.Logger {
-	logger := ctx.Locals("logger").(*zap.Logger)
+// Logger is a backend-agnostic structured logger. Handlers should depend
+// on this interface rather than a concrete logging library type (e.g.
+// *zap.Logger) so the logging backend can be swapped without touching
+// handler code.
+type Logger interface {
+	// With returns a derived Logger that includes the given key/value
+	// pairs on every subsequent record.
+	With(args ...any) Logger
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// GetLogger returns the Logger bound to the request by the logging
+// middleware.
+func GetLogger(ctx *fiber.Ctx) Logger {
+	logger := ctx.Locals("logger").(Logger)
 	if logger == nil {
 		panic(errors.New("logger is nil"))
 	}
@@ -1,16 +1,52 @@
 package request
 
 import (
+	"context"
 	"errors"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/openzipkin/zipkin-go"
 )
 
-func GetTracer(ctx *fiber.Ctx) *zipkin.Tracer {
-	tracer := ctx.Locals("tracer").(*zipkin.Tracer)
+// Span is a backend-agnostic handle to an in-flight trace span. Handlers
+// should depend on this interface rather than a concrete tracer's span
+// type (e.g. *zipkin.Span) so the telemetry backend can be swapped via
+// TELEMETRY_BACKEND without touching handler code.
+type Span interface {
+	// StartChild starts a new child span named name, returning the child
+	// span and a context carrying it for further nesting.
+	StartChild(ctx context.Context, name string) (Span, context.Context)
+	// Tag attaches a key/value annotation to the span.
+	Tag(key, value string)
+	// Inject writes this span's trace-context propagation headers (B3 or
+	// W3C, whichever the backend natively uses) into headers, so a
+	// handler can attach them to an outbound call it makes downstream and
+	// have the receiving side continue the same trace.
+	Inject(headers map[string]string)
+	// Finish marks the span as complete.
+	Finish()
+}
+
+// Tracer starts root spans from a request context.
+type Tracer interface {
+	StartSpanFromContext(ctx context.Context, name string) (Span, context.Context)
+}
+
+// GetTracer returns the Tracer bound to the request by the telemetry
+// middleware.
+func GetTracer(ctx *fiber.Ctx) Tracer {
+	tracer := ctx.Locals("tracer").(Tracer)
 	if tracer == nil {
 		panic(errors.New("tracer is nil"))
 	}
 	return tracer
 }
+
+// GetSpan returns the root Span the telemetry middleware started for the
+// current request.
+func GetSpan(ctx *fiber.Ctx) Span {
+	span := ctx.Locals("parentSpan").(Span)
+	if span == nil {
+		panic(errors.New("parentSpan is nil"))
+	}
+	return span
+}
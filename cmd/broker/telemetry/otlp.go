@@ -0,0 +1,202 @@
+package telemetry
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+
+	"github.com/alexchomiak/broker/cmd/broker/request"
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/credentials"
+)
+
+// otelSpan adapts an OpenTelemetry oteltrace.Span to the backend-agnostic
+// request.Span.
+type otelSpan struct {
+	span   oteltrace.Span
+	tracer oteltrace.Tracer
+}
+
+func (s *otelSpan) StartChild(ctx context.Context, name string) (request.Span, context.Context) {
+	childCtx, child := s.tracer.Start(ctx, name)
+	return &otelSpan{span: child, tracer: s.tracer}, childCtx
+}
+
+func (s *otelSpan) Tag(key, value string) {
+	s.span.SetAttributes(attribute.String(key, value))
+}
+
+// Inject writes this span's context as a W3C traceparent header, OTel's
+// native propagation scheme, for an outbound call this handler makes
+// downstream.
+func (s *otelSpan) Inject(headers map[string]string) {
+	injectHeaders(otelCarriedContextFrom(s.span.SpanContext()), func(k, v string) { headers[k] = v })
+}
+
+func (s *otelSpan) Finish() { s.span.End() }
+
+// otelTracer adapts an oteltrace.Tracer to the backend-agnostic
+// request.Tracer.
+type otelTracer struct {
+	tracer oteltrace.Tracer
+}
+
+func (t *otelTracer) StartSpanFromContext(ctx context.Context, name string) (request.Span, context.Context) {
+	spanCtx, span := t.tracer.Start(ctx, name)
+	return &otelSpan{span: span, tracer: t.tracer}, spanCtx
+}
+
+// otlpProvider exports traces and metrics over OTLP (gRPC) instead of the
+// Zipkin HTTP reporter and Prometheus scrape endpoint.
+type otlpProvider struct {
+	tracerProvider *sdktrace.TracerProvider
+	meterProvider  *sdkmetric.MeterProvider
+	tracer         oteltrace.Tracer
+}
+
+func newOTLPProvider(cfg Config, log *zap.Logger) (Provider, error) {
+	ctx := context.Background()
+
+	res, err := newResource(cfg.ServiceName)
+	if err != nil {
+		return nil, fmt.Errorf("telemetry: building OTLP resource: %w", err)
+	}
+
+	traceOpts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.OTLPEndpoint)}
+	if cfg.OTLPInsecure {
+		traceOpts = append(traceOpts, otlptracegrpc.WithInsecure())
+	} else {
+		traceOpts = append(traceOpts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(&tls.Config{})))
+	}
+	traceExporter, err := otlptracegrpc.New(ctx, traceOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("telemetry: building OTLP trace exporter: %w", err)
+	}
+
+	metricOpts := []otlpmetricgrpc.Option{otlpmetricgrpc.WithEndpoint(cfg.OTLPEndpoint)}
+	if cfg.OTLPInsecure {
+		metricOpts = append(metricOpts, otlpmetricgrpc.WithInsecure())
+	} else {
+		metricOpts = append(metricOpts, otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(&tls.Config{})))
+	}
+	metricExporter, err := otlpmetricgrpc.New(ctx, metricOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("telemetry: building OTLP metric exporter: %w", err)
+	}
+
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(traceExporter),
+		sdktrace.WithResource(res),
+	)
+	meterProvider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter)),
+		sdkmetric.WithResource(res),
+	)
+
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetMeterProvider(meterProvider)
+
+	return &otlpProvider{
+		tracerProvider: tracerProvider,
+		meterProvider:  meterProvider,
+		tracer:         tracerProvider.Tracer(cfg.ServiceName),
+	}, nil
+}
+
+func newResource(serviceName string) (*resource.Resource, error) {
+	return resource.Merge(
+		resource.Default(),
+		resource.NewSchemaless(semconv.ServiceNameKey.String(serviceName)),
+	)
+}
+
+func (p *otlpProvider) Tracer() request.Tracer {
+	return &otelTracer{tracer: p.tracer}
+}
+
+func (p *otlpProvider) Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.UserContext()
+		scheme := "w3c"
+		if cc, ok := extractCarriedContext(c); ok {
+			scheme = cc.scheme
+			if sc, ok := otelSpanContextFrom(cc); ok {
+				ctx = oteltrace.ContextWithRemoteSpanContext(ctx, sc)
+			}
+		}
+
+		spanCtx, span := p.tracer.Start(ctx, c.Route().Path)
+		rspan := &otelSpan{span: span, tracer: p.tracer}
+
+		c.Locals("tracer", request.Tracer(&otelTracer{tracer: p.tracer}))
+		c.Locals("parentSpan", request.Span(rspan))
+		c.SetUserContext(spanCtx)
+
+		egress := otelCarriedContextFrom(span.SpanContext())
+		egress.scheme = scheme
+		injectCarriedContext(c, egress)
+
+		err := c.Next()
+		rspan.Finish()
+		return err
+	}
+}
+
+// MetricsHandler returns nil: OTLP metrics are pushed to the collector on
+// the periodic reader's interval rather than scraped.
+func (p *otlpProvider) MetricsHandler(gatherer prometheus.Gatherer) fasthttp.RequestHandler {
+	return nil
+}
+
+func (p *otlpProvider) Shutdown(ctx context.Context) error {
+	if err := p.tracerProvider.Shutdown(ctx); err != nil {
+		return err
+	}
+	return p.meterProvider.Shutdown(ctx)
+}
+
+// otelSpanContextFrom translates a carriedContext parsed off the wire into
+// an OpenTelemetry remote SpanContext.
+func otelSpanContextFrom(cc carriedContext) (oteltrace.SpanContext, bool) {
+	traceID, err := oteltrace.TraceIDFromHex(cc.traceID)
+	if err != nil {
+		return oteltrace.SpanContext{}, false
+	}
+	spanID, err := oteltrace.SpanIDFromHex(cc.spanID)
+	if err != nil {
+		return oteltrace.SpanContext{}, false
+	}
+
+	flags := oteltrace.TraceFlags(0)
+	if cc.sampled {
+		flags = oteltrace.FlagsSampled
+	}
+
+	return oteltrace.NewSpanContext(oteltrace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: flags,
+		Remote:     true,
+	}), true
+}
+
+func otelCarriedContextFrom(sc oteltrace.SpanContext) carriedContext {
+	return carriedContext{
+		traceID: sc.TraceID().String(),
+		spanID:  sc.SpanID().String(),
+		sampled: sc.IsSampled(),
+		scheme:  "w3c",
+	}
+}
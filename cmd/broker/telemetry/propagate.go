@@ -0,0 +1,134 @@
+package telemetry
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// * Both header schemes are read on ingress so the broker accepts upstream
+// * callers using either convention; only one is written on egress per
+// * carriedContext, picked by whichever scheme the ingress context arrived
+// * in (defaulting to W3C for locally-originated spans).
+
+const (
+	b3TraceIDHeader  = "X-B3-TraceId"
+	b3SpanIDHeader   = "X-B3-SpanId"
+	b3SampledHeader  = "X-B3-Sampled"
+	b3SingleHeader   = "b3"
+	traceparentHdr   = "traceparent"
+	traceparentVer   = "00"
+	traceFlagsSample = "01"
+)
+
+// carriedContext is the minimal trace context extracted from inbound
+// headers, independent of backend-specific span representations.
+type carriedContext struct {
+	traceID string
+	spanID  string
+	sampled bool
+	scheme  string // "b3" | "w3c" | ""
+}
+
+// extractCarriedContext reads B3 (single or multi-header) and W3C
+// traceparent headers off the incoming request, preferring traceparent
+// when both are present.
+func extractCarriedContext(ctx *fiber.Ctx) (carriedContext, bool) {
+	if tp := ctx.Get(traceparentHdr); tp != "" {
+		if cc, ok := parseTraceparent(tp); ok {
+			return cc, true
+		}
+	}
+
+	if single := ctx.Get(b3SingleHeader); single != "" {
+		if cc, ok := parseB3Single(single); ok {
+			return cc, true
+		}
+	}
+
+	traceID := ctx.Get(b3TraceIDHeader)
+	spanID := ctx.Get(b3SpanIDHeader)
+	if traceID != "" && spanID != "" {
+		return carriedContext{
+			traceID: traceID,
+			spanID:  spanID,
+			sampled: ctx.Get(b3SampledHeader) != "0",
+			scheme:  "b3",
+		}, true
+	}
+
+	return carriedContext{}, false
+}
+
+// injectCarriedContext writes cc back onto outbound response headers using
+// its original scheme, so downstream hops that only understand one scheme
+// still see one.
+func injectCarriedContext(ctx *fiber.Ctx, cc carriedContext) {
+	injectHeaders(cc, ctx.Set)
+}
+
+// injectHeaders writes cc using set, which may point at an inbound
+// response (ctx.Set) or an outbound request's header map - whatever the
+// caller needs cc's propagation headers attached to.
+func injectHeaders(cc carriedContext, set func(key, value string)) {
+	switch cc.scheme {
+	case "w3c":
+		set(traceparentHdr, formatTraceparent(cc))
+	default:
+		set(b3TraceIDHeader, cc.traceID)
+		set(b3SpanIDHeader, cc.spanID)
+		if cc.sampled {
+			set(b3SampledHeader, "1")
+		} else {
+			set(b3SampledHeader, "0")
+		}
+	}
+}
+
+// parseTraceparent parses a W3C "traceparent" header:
+// version-traceid-spanid-flags
+func parseTraceparent(header string) (carriedContext, bool) {
+	parts := strings.Split(header, "-")
+	if len(parts) != 4 {
+		return carriedContext{}, false
+	}
+	flags, err := strconv.ParseUint(parts[3], 16, 8)
+	if err != nil {
+		return carriedContext{}, false
+	}
+	return carriedContext{
+		traceID: parts[1],
+		spanID:  parts[2],
+		sampled: flags&0x1 == 1,
+		scheme:  "w3c",
+	}, true
+}
+
+func formatTraceparent(cc carriedContext) string {
+	flags := "00"
+	if cc.sampled {
+		flags = traceFlagsSample
+	}
+	return fmt.Sprintf("%s-%s-%s-%s", traceparentVer, cc.traceID, cc.spanID, flags)
+}
+
+// parseB3Single parses the single-header B3 form:
+// traceid-spanid-sampled
+func parseB3Single(header string) (carriedContext, bool) {
+	parts := strings.Split(header, "-")
+	if len(parts) < 2 {
+		return carriedContext{}, false
+	}
+	cc := carriedContext{
+		traceID: parts[0],
+		spanID:  parts[1],
+		sampled: true,
+		scheme:  "b3",
+	}
+	if len(parts) >= 3 {
+		cc.sampled = parts[2] != "0"
+	}
+	return cc, true
+}
@@ -0,0 +1,189 @@
+package telemetry
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// extractFromHeaders runs extractCarriedContext against a request carrying
+// headers, via a throwaway fiber app - extractCarriedContext takes a
+// *fiber.Ctx, not a header map, so there's no way to call it without one.
+func extractFromHeaders(t *testing.T, headers map[string]string) (carriedContext, bool) {
+	var got carriedContext
+	var ok bool
+
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		got, ok = extractCarriedContext(c)
+		return nil
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	return got, ok
+}
+
+func TestExtractCarriedContextPrefersTraceparentOverB3(t *testing.T) {
+	cc, ok := extractFromHeaders(t, map[string]string{
+		traceparentHdr:  "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		b3TraceIDHeader: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+		b3SpanIDHeader:  "bbbbbbbbbbbbbbbb",
+	})
+	if !ok {
+		t.Fatal("expected a carried context to be extracted")
+	}
+	if cc.scheme != "w3c" {
+		t.Fatalf("expected traceparent to take precedence over B3 headers, got scheme %q", cc.scheme)
+	}
+	if cc.traceID != "4bf92f3577b34da6a3ce929d0e0e4736" || cc.spanID != "00f067aa0ba902b7" {
+		t.Fatalf("unexpected trace/span ID: %+v", cc)
+	}
+	if !cc.sampled {
+		t.Fatal("expected sampled flag 01 to parse as sampled")
+	}
+}
+
+func TestExtractCarriedContextB3Single(t *testing.T) {
+	cc, ok := extractFromHeaders(t, map[string]string{
+		b3SingleHeader: "4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0",
+	})
+	if !ok {
+		t.Fatal("expected a carried context to be extracted")
+	}
+	if cc.scheme != "b3" {
+		t.Fatalf("expected scheme b3, got %q", cc.scheme)
+	}
+	if cc.sampled {
+		t.Fatal("expected explicit sampled=0 to parse as not sampled")
+	}
+}
+
+func TestExtractCarriedContextB3Multi(t *testing.T) {
+	cc, ok := extractFromHeaders(t, map[string]string{
+		b3TraceIDHeader: "4bf92f3577b34da6a3ce929d0e0e4736",
+		b3SpanIDHeader:  "00f067aa0ba902b7",
+	})
+	if !ok {
+		t.Fatal("expected a carried context to be extracted")
+	}
+	if cc.scheme != "b3" {
+		t.Fatalf("expected scheme b3, got %q", cc.scheme)
+	}
+	if !cc.sampled {
+		t.Fatal("expected sampled to default to true when X-B3-Sampled is absent")
+	}
+}
+
+func TestExtractCarriedContextB3MultiNotSampled(t *testing.T) {
+	cc, ok := extractFromHeaders(t, map[string]string{
+		b3TraceIDHeader: "4bf92f3577b34da6a3ce929d0e0e4736",
+		b3SpanIDHeader:  "00f067aa0ba902b7",
+		b3SampledHeader: "0",
+	})
+	if !ok {
+		t.Fatal("expected a carried context to be extracted")
+	}
+	if cc.sampled {
+		t.Fatal("expected X-B3-Sampled: 0 to parse as not sampled")
+	}
+}
+
+func TestExtractCarriedContextFallsBackOnMalformedTraceparent(t *testing.T) {
+	cc, ok := extractFromHeaders(t, map[string]string{
+		traceparentHdr:  "not-a-valid-traceparent",
+		b3TraceIDHeader: "4bf92f3577b34da6a3ce929d0e0e4736",
+		b3SpanIDHeader:  "00f067aa0ba902b7",
+	})
+	if !ok {
+		t.Fatal("expected the malformed traceparent to be skipped in favor of the valid B3 headers")
+	}
+	if cc.scheme != "b3" {
+		t.Fatalf("expected fallback to B3, got scheme %q", cc.scheme)
+	}
+}
+
+func TestExtractCarriedContextNoHeaders(t *testing.T) {
+	_, ok := extractFromHeaders(t, nil)
+	if ok {
+		t.Fatal("expected no carried context when no trace headers are present")
+	}
+}
+
+func TestParseTraceparentRejectsWrongPartCount(t *testing.T) {
+	if _, ok := parseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7"); ok {
+		t.Fatal("expected a 3-part traceparent to be rejected")
+	}
+}
+
+func TestParseTraceparentRejectsBadFlags(t *testing.T) {
+	if _, ok := parseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-zz"); ok {
+		t.Fatal("expected non-hex flags to be rejected")
+	}
+}
+
+func TestParseB3SingleRequiresTraceAndSpanID(t *testing.T) {
+	if _, ok := parseB3Single("4bf92f3577b34da6a3ce929d0e0e4736"); ok {
+		t.Fatal("expected a single-part b3 header to be rejected")
+	}
+}
+
+func TestParseB3SingleDefaultsSampledTrue(t *testing.T) {
+	cc, ok := parseB3Single("4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7")
+	if !ok {
+		t.Fatal("expected a trace/span-id-only b3 header to parse")
+	}
+	if !cc.sampled {
+		t.Fatal("expected sampled to default to true when the sampled segment is omitted")
+	}
+}
+
+func TestInjectHeadersW3C(t *testing.T) {
+	headers := map[string]string{}
+	injectHeaders(carriedContext{
+		traceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		spanID:  "00f067aa0ba902b7",
+		sampled: true,
+		scheme:  "w3c",
+	}, func(k, v string) { headers[k] = v })
+
+	want := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	if headers[traceparentHdr] != want {
+		t.Fatalf("expected traceparent %q, got %q", want, headers[traceparentHdr])
+	}
+	if _, ok := headers[b3TraceIDHeader]; ok {
+		t.Fatal("expected no B3 headers to be written for a w3c-scheme context")
+	}
+}
+
+func TestInjectHeadersB3(t *testing.T) {
+	headers := map[string]string{}
+	injectHeaders(carriedContext{
+		traceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		spanID:  "00f067aa0ba902b7",
+		sampled: false,
+		scheme:  "b3",
+	}, func(k, v string) { headers[k] = v })
+
+	if headers[b3TraceIDHeader] != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("unexpected %s: %q", b3TraceIDHeader, headers[b3TraceIDHeader])
+	}
+	if headers[b3SpanIDHeader] != "00f067aa0ba902b7" {
+		t.Fatalf("unexpected %s: %q", b3SpanIDHeader, headers[b3SpanIDHeader])
+	}
+	if headers[b3SampledHeader] != "0" {
+		t.Fatalf("expected %s=0 for an unsampled context, got %q", b3SampledHeader, headers[b3SampledHeader])
+	}
+	if _, ok := headers[traceparentHdr]; ok {
+		t.Fatal("expected no traceparent header to be written for a b3-scheme context")
+	}
+}
@@ -0,0 +1,73 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/alexchomiak/broker/cmd/broker/request"
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// Provider wires a tracing/metrics backend into the broker service. Both
+// the Zipkin+Prometheus stack and the OpenTelemetry OTLP stack implement
+// this so main.go can select between them with TELEMETRY_BACKEND instead
+// of branching on backend-specific types.
+type Provider interface {
+	// Tracer returns the request.Tracer handlers and middleware should use
+	// to start spans for incoming requests.
+	Tracer() request.Tracer
+	// Middleware returns the Fiber middleware that starts a root span per
+	// request, extracting upstream trace context (B3 and/or W3C
+	// traceparent, depending on the backend) and injecting it downstream.
+	Middleware() fiber.Handler
+	// MetricsHandler returns the fasthttp handler to serve on /metrics,
+	// gathering from gatherer, or nil if this backend pushes metrics
+	// out-of-band instead of exposing a scrape endpoint.
+	MetricsHandler(gatherer prometheus.Gatherer) fasthttp.RequestHandler
+	// Shutdown flushes and releases any backend resources (reporters,
+	// exporters, connections).
+	Shutdown(ctx context.Context) error
+}
+
+// Config selects a backend and holds its connection details. It is
+// populated from environment variables by NewProviderFromEnv.
+type Config struct {
+	Backend        string // "zipkin" | "otlp"
+	ServiceName    string
+	ZipkinEndpoint string
+	OTLPEndpoint   string
+	OTLPInsecure   bool
+}
+
+// NewProviderFromEnv builds a Provider from TELEMETRY_BACKEND and its
+// associated backend-specific environment variables, defaulting to the
+// existing Zipkin+Prometheus stack when unset.
+func NewProviderFromEnv(log *zap.Logger) (Provider, error) {
+	cfg := Config{
+		Backend:        os.Getenv("TELEMETRY_BACKEND"),
+		ServiceName:    envOr("TELEMETRY_SERVICE_NAME", "broker"),
+		ZipkinEndpoint: envOr("ZIPKIN_ENDPOINT", "http://localhost:9411/api/v2/spans"),
+		OTLPEndpoint:   envOr("OTLP_ENDPOINT", "localhost:4317"),
+		OTLPInsecure:   envOr("OTLP_INSECURE", "true") == "true",
+	}
+
+	switch cfg.Backend {
+	case "", "zipkin":
+		return newZipkinProvider(cfg, log)
+	case "otlp":
+		return newOTLPProvider(cfg, log)
+	default:
+		return nil, fmt.Errorf("unknown TELEMETRY_BACKEND %q", cfg.Backend)
+	}
+}
+
+func envOr(key, fallback string) string {
+	if v, ok := os.LookupEnv(key); ok {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,154 @@
+package telemetry
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/alexchomiak/broker/cmd/broker/request"
+	"github.com/gofiber/fiber/v2"
+	"github.com/openzipkin/zipkin-go"
+	zipkinmodel "github.com/openzipkin/zipkin-go/model"
+	"github.com/openzipkin/zipkin-go/reporter"
+	reporterhttp "github.com/openzipkin/zipkin-go/reporter/http"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+	"go.uber.org/zap"
+)
+
+// zipkinSpan adapts a zipkin.Span to the backend-agnostic request.Span.
+type zipkinSpan struct {
+	span   zipkin.Span
+	tracer *zipkin.Tracer
+}
+
+func (s *zipkinSpan) StartChild(ctx context.Context, name string) (request.Span, context.Context) {
+	child, childCtx := s.tracer.StartSpanFromContext(ctx, name)
+	return &zipkinSpan{span: child, tracer: s.tracer}, childCtx
+}
+
+func (s *zipkinSpan) Tag(key, value string) { s.span.Tag(key, value) }
+
+// Inject writes this span's context as B3 headers, zipkin's native
+// propagation scheme, for an outbound call this handler makes downstream.
+func (s *zipkinSpan) Inject(headers map[string]string) {
+	injectHeaders(carriedContextFrom(s.span.Context(), "b3"), func(k, v string) { headers[k] = v })
+}
+
+func (s *zipkinSpan) Finish() { s.span.Finish() }
+
+// zipkinTracer adapts a *zipkin.Tracer to the backend-agnostic
+// request.Tracer.
+type zipkinTracer struct {
+	tracer *zipkin.Tracer
+}
+
+func (t *zipkinTracer) StartSpanFromContext(ctx context.Context, name string) (request.Span, context.Context) {
+	span, spanCtx := t.tracer.StartSpanFromContext(ctx, name)
+	return &zipkinSpan{span: span, tracer: t.tracer}, spanCtx
+}
+
+// zipkinProvider is the Zipkin HTTP reporter + Prometheus scrape endpoint
+// stack main.go used to build inline, with the same local endpoint and
+// sampler. Unlike that inline version, root spans are named by the matched
+// route template rather than the concrete path, and B3/W3C trace context
+// is extracted on ingress and injected on egress.
+type zipkinProvider struct {
+	tracer   *zipkin.Tracer
+	reporter reporter.Reporter
+}
+
+func newZipkinProvider(cfg Config, log *zap.Logger) (Provider, error) {
+	localEndpoint, err := zipkin.NewEndpoint(cfg.ServiceName, "localhost:9411")
+	if err != nil {
+		return nil, err
+	}
+
+	rep := reporterhttp.NewReporter(cfg.ZipkinEndpoint)
+	sampler, err := zipkin.NewCountingSampler(1.0)
+	if err != nil {
+		return nil, err
+	}
+
+	tracer, err := zipkin.NewTracer(
+		rep,
+		zipkin.WithLocalEndpoint(localEndpoint),
+		zipkin.WithSampler(sampler),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &zipkinProvider{tracer: tracer, reporter: rep}, nil
+}
+
+func (p *zipkinProvider) Tracer() request.Tracer {
+	return &zipkinTracer{tracer: p.tracer}
+}
+
+func (p *zipkinProvider) Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var opts []zipkin.SpanOption
+		scheme := "w3c"
+		if cc, ok := extractCarriedContext(c); ok {
+			scheme = cc.scheme
+			if sc, ok := zipkinSpanContextFrom(cc); ok {
+				opts = append(opts, zipkin.Parent(sc))
+			}
+		}
+
+		span, spanCtx := p.tracer.StartSpanFromContext(c.UserContext(), c.Route().Path, opts...)
+		rspan := &zipkinSpan{span: span, tracer: p.tracer}
+
+		c.Locals("tracer", request.Tracer(&zipkinTracer{tracer: p.tracer}))
+		c.Locals("parentSpan", request.Span(rspan))
+		c.SetUserContext(spanCtx)
+
+		injectCarriedContext(c, carriedContextFrom(span.Context(), scheme))
+
+		err := c.Next()
+		rspan.Finish()
+		return err
+	}
+}
+
+func (p *zipkinProvider) MetricsHandler(gatherer prometheus.Gatherer) fasthttp.RequestHandler {
+	return fasthttpadaptor.NewFastHTTPHandler(promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
+}
+
+func (p *zipkinProvider) Shutdown(ctx context.Context) error {
+	return p.reporter.Close()
+}
+
+// zipkinSpanContextFrom translates a carriedContext parsed off the wire
+// into a zipkin model.SpanContext suitable for zipkin.Parent.
+func zipkinSpanContextFrom(cc carriedContext) (zipkinmodel.SpanContext, bool) {
+	traceID, err := zipkinmodel.TraceIDFromHex(cc.traceID)
+	if err != nil {
+		return zipkinmodel.SpanContext{}, false
+	}
+	spanID, err := strconv.ParseUint(cc.spanID, 16, 64)
+	if err != nil {
+		return zipkinmodel.SpanContext{}, false
+	}
+	id := zipkinmodel.ID(spanID)
+	return zipkinmodel.SpanContext{
+		TraceID: traceID,
+		ID:      id,
+		Sampled: &cc.sampled,
+	}, true
+}
+
+// carriedContextFrom converts a zipkin span's own context back into the
+// wire-level representation, so it can be re-injected on egress in
+// whichever scheme the request arrived in.
+func carriedContextFrom(sc zipkinmodel.SpanContext, scheme string) carriedContext {
+	sampled := sc.Sampled != nil && *sc.Sampled
+	return carriedContext{
+		traceID: sc.TraceID.String(),
+		spanID:  strconv.FormatUint(uint64(sc.ID), 16),
+		sampled: sampled,
+		scheme:  scheme,
+	}
+}